@@ -3,13 +3,109 @@ package gcsobj
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
+	"sync"
 	"sync/atomic"
 
 	"cloud.google.com/go/storage"
 )
 
+// ErrChecksumMismatch is returned from Read when ReaderOptions.VerifyCRC32C
+// is set, the object was read contiguously from byte 0 through EOF, and the
+// CRC32C computed over the bytes read does not match the value stored in
+// the object's attributes.
+var ErrChecksumMismatch = errors.New("gcsobj: CRC32C checksum mismatch")
+
+// rangeOpener is the subset of *storage.ObjectHandle that Reader needs in
+// order to open range reads. It exists so tests can substitute a fake
+// implementation instead of talking to real GCS.
+type rangeOpener interface {
+	NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+}
+
+// objectHandleOpener adapts *storage.ObjectHandle to rangeOpener.
+type objectHandleOpener struct {
+	obj *storage.ObjectHandle
+}
+
+func (o objectHandleOpener) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	return o.obj.NewRangeReader(ctx, offset, length)
+}
+
+// ReaderOptions controls the adaptive range-request behavior of a Reader.
+//
+// By default a Reader guesses whether it is being read sequentially or
+// randomly and sizes its underlying GCS range requests accordingly: small
+// windows (MinReadSize) for scattered access, widening to the rest of the
+// object once access looks sequential. Zero-valued fields are replaced with
+// the corresponding [DefaultReaderOptions] value.
+type ReaderOptions struct {
+	// MinReadSize is the minimum number of bytes requested in each
+	// underlying GCS range read. A small Read call still causes this many
+	// bytes to be fetched (clamped to the end of the object), so that
+	// later small, nearby Reads can be served without another HTTP
+	// request.
+	MinReadSize int64
+
+	// UpgradeSeekThreshold is the maximum number of seeks (non-sequential
+	// Read calls) a Reader may have counted and still upgrade to an
+	// unbounded range read. The count is cleared once UpgradeBytesThreshold
+	// sequential bytes have accumulated since the last seek, which is also
+	// what an upgrade requires, so this limit does not by itself prevent
+	// an upgrade.
+	UpgradeSeekThreshold int
+
+	// UpgradeBytesThreshold is the number of sequential bytes (read, or
+	// skipped by an in-window forward Seek) that must accumulate since the
+	// last seek before a Reader upgrades to an unbounded range read that
+	// runs to the end of the object.
+	UpgradeBytesThreshold int64
+
+	// Disabled reverts to the pre-adaptive behavior: every underlying
+	// range read runs unconditionally to the end of the object.
+	Disabled bool
+
+	// MaxForwardSkip is the largest forward jump, in bytes, that a Seek
+	// call will satisfy by discarding bytes from the currently open
+	// range read rather than closing it and opening a new one. Forward
+	// seeks beyond this window, and all backward seeks, close the
+	// underlying range reader as before.
+	MaxForwardSkip int64
+
+	// ReadAtChunkSize, together with ReadAtConcurrency, splits large
+	// ReadAt calls into chunks of this many bytes fetched concurrently.
+	// Zero (the default) leaves ReadAt calls unsplit: each is a single
+	// range read regardless of size.
+	ReadAtChunkSize int64
+
+	// ReadAtConcurrency caps the number of chunk reads that a single
+	// ReadAt call runs at once when ReadAtChunkSize is set. Values less
+	// than 2 leave ReadAt calls unsplit.
+	ReadAtConcurrency int
+
+	// VerifyCRC32C, when true, computes the CRC32C (Castagnoli) checksum
+	// of the bytes delivered by Read and compares it against the
+	// object's stored CRC32C once Read reaches EOF having covered the
+	// object contiguously from offset 0. It has no effect unless the
+	// Reader was created with attrs available (see [NewReaderWithAttrs]),
+	// since that is where the stored CRC32C comes from.
+	VerifyCRC32C bool
+}
+
+// DefaultReaderOptions is the ReaderOptions used by NewReader and
+// NewReaderWithSize, and the source of defaults for zero fields passed to
+// NewReaderWithOptions.
+var DefaultReaderOptions = ReaderOptions{
+	MinReadSize:           1 << 20,
+	UpgradeSeekThreshold:  2,
+	UpgradeBytesThreshold: 8 << 20,
+	MaxForwardSkip:        1 << 20,
+}
+
 // Reader is an io.ReadSeeker for objects in Google Cloud Storage buckets.
 type Reader struct {
 	// Embedding a context in a data structure is an antipattern,
@@ -17,74 +113,338 @@ type Reader struct {
 	// See https://go.dev/wiki/CodeReviewComments#contexts
 	ctx context.Context
 
-	obj       *storage.ObjectHandle
-	r         *storage.Reader
+	obj       rangeOpener
+	r         io.ReadCloser
+	rangeEnd  int64 // absolute offset where the open r.r ends, valid iff r != nil
 	pos, size int64
 	nread     int64 // Read/write with atomic
+
+	opts ReaderOptions
+
+	started    bool  // whether Read or WriteTo has been called at least once (tracked unless opts.Disabled)
+	lastEndPos int64 // r.pos as of the end of the previous Read, WriteTo or in-window forward Seek
+	seeks      int64 // count of non-sequential Read calls, cleared by rangeLength after a long sequential run
+	seqBytes   int64 // contiguous bytes read or skipped in-window since the last seek
+
+	wantCRC32C uint32      // the object's stored CRC32C, valid iff haveCRC32C
+	haveCRC32C bool        // whether wantCRC32C came from attrs
+	crc        hash.Hash32 // non-nil iff opts.VerifyCRC32C && haveCRC32C
+	crcBroken  bool        // whether a seek has broken contiguity from byte 0
+	crcDone    bool        // whether the EOF comparison has already run
+	verified   bool        // whether that comparison ran and matched
 }
 
-// NewReader creates a new Reader on the given object.
-// If the object size is already known, use [NewReaderWithSize] instead.
+// NewReader creates a new Reader on the given object, using
+// DefaultReaderOptions. Because this fetches the object's attrs anyway, the
+// resulting Reader has its stored CRC32C available for
+// ReaderOptions.VerifyCRC32C.
+// If the object size (or attrs) is already known, use [NewReaderWithSize] or
+// [NewReaderWithAttrs] instead to avoid the extra RPC.
 // Callers must call the Close method when finished with the Reader.
 func NewReader(ctx context.Context, obj *storage.ObjectHandle) (*Reader, error) {
 	attrs, err := obj.Attrs(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return NewReaderWithSize(ctx, obj, attrs.Size), nil
+	return NewReaderWithAttrs(ctx, obj, attrs), nil
 }
 
-// NewReaderWithSize creates a new Reader on the given object.
+// NewReaderWithSize creates a new Reader on the given object, using
+// DefaultReaderOptions.
 // Use this in preference to [NewReader] when the object size is already known
-// (e.g. from an earlier call to [storage.ObjectHandle.Attrs]).
+// (e.g. from an earlier call to [storage.ObjectHandle.Attrs]). Because only
+// the size is known, not the full attrs, the resulting Reader cannot verify
+// a CRC32C even if ReaderOptions.VerifyCRC32C is set; use
+// [NewReaderWithAttrs] for that.
 // Callers must call the Close method when finished with the Reader.
 func NewReaderWithSize(ctx context.Context, obj *storage.ObjectHandle, size int64) *Reader {
+	return NewReaderWithOptions(ctx, obj, size, DefaultReaderOptions)
+}
+
+// NewReaderWithAttrs creates a new Reader on the given object using attrs
+// already fetched by the caller (e.g. from an earlier
+// [storage.ObjectHandle.Attrs] call), using DefaultReaderOptions. Passing
+// attrs is what lets a Reader verify the object's CRC32C when
+// ReaderOptions.VerifyCRC32C is set, without the extra RPC that NewReader
+// performs to get them.
+// Callers must call the Close method when finished with the Reader.
+func NewReaderWithAttrs(ctx context.Context, obj *storage.ObjectHandle, attrs *storage.ObjectAttrs) *Reader {
+	return NewReaderWithAttrsAndOptions(ctx, obj, attrs, DefaultReaderOptions)
+}
+
+// NewReaderWithAttrsAndOptions is [NewReaderWithAttrs] with explicit
+// ReaderOptions. Zero fields in opts are replaced with the corresponding
+// DefaultReaderOptions value, as in [NewReaderWithOptions].
+// Callers must call the Close method when finished with the Reader.
+func NewReaderWithAttrsAndOptions(ctx context.Context, obj *storage.ObjectHandle, attrs *storage.ObjectAttrs, opts ReaderOptions) *Reader {
+	r := NewReaderWithOptions(ctx, obj, attrs.Size, opts)
+	r.wantCRC32C = attrs.CRC32C
+	r.haveCRC32C = true
+	if opts.VerifyCRC32C {
+		r.crc = crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	}
+	return r
+}
+
+// NewReaderWithOptions creates a new Reader on the given object with the
+// object size already known (as in [NewReaderWithSize]) and with the given
+// ReaderOptions. Zero fields in opts are replaced with the corresponding
+// DefaultReaderOptions value.
+// Callers must call the Close method when finished with the Reader.
+func NewReaderWithOptions(ctx context.Context, obj *storage.ObjectHandle, size int64, opts ReaderOptions) *Reader {
+	if opts.MinReadSize <= 0 {
+		opts.MinReadSize = DefaultReaderOptions.MinReadSize
+	}
+	if opts.UpgradeSeekThreshold <= 0 {
+		opts.UpgradeSeekThreshold = DefaultReaderOptions.UpgradeSeekThreshold
+	}
+	if opts.UpgradeBytesThreshold <= 0 {
+		opts.UpgradeBytesThreshold = DefaultReaderOptions.UpgradeBytesThreshold
+	}
+	if opts.MaxForwardSkip <= 0 {
+		opts.MaxForwardSkip = DefaultReaderOptions.MaxForwardSkip
+	}
 	return &Reader{
 		ctx:  ctx,
-		obj:  obj,
+		obj:  objectHandleOpener{obj},
 		size: size,
+		opts: opts,
 	}
 }
 
+// rangeLength picks the length to request from obj.NewRangeReader for a new
+// range read starting at r.pos: -1 (meaning "to the end of the object") once
+// access looks sequential and seeks are rare, otherwise r.opts.MinReadSize
+// clamped to what remains of the object.
+func (r *Reader) rangeLength() int64 {
+	if r.opts.Disabled {
+		return -1
+	}
+	if r.seqBytes >= r.opts.UpgradeBytesThreshold {
+		// Enough sequential bytes have accumulated since the last seek to
+		// show that the current access pattern is sequential, regardless
+		// of how many seeks came before it. Without this, a handful of
+		// seeks early in a Reader's life (e.g. reading a zip/tar header)
+		// would push r.seeks past UpgradeSeekThreshold once and for all,
+		// permanently disqualifying an otherwise long sequential run from
+		// ever upgrading.
+		r.seeks = 0
+	}
+	if r.seeks <= int64(r.opts.UpgradeSeekThreshold) && r.seqBytes >= r.opts.UpgradeBytesThreshold {
+		return -1
+	}
+	length := r.opts.MinReadSize
+	if r.pos+length > r.size {
+		length = r.size - r.pos
+	}
+	return length
+}
+
 // Read implements io.Reader.
 func (r *Reader) Read(dest []byte) (int, error) {
-	if r.r == nil && r.pos < r.size {
-		var err error
-		r.r, err = r.obj.NewRangeReader(r.ctx, r.pos, -1)
-		if err != nil {
-			return 0, err
+	if !r.opts.Disabled {
+		if r.started && r.pos != r.lastEndPos {
+			r.seeks++
+			r.seqBytes = 0
 		}
+		r.started = true
 	}
-	if r.r == nil {
-		return 0, io.EOF
+
+	for {
+		if r.r == nil && r.pos < r.size {
+			if err := r.openRange(r.rangeLength()); err != nil {
+				return 0, err
+			}
+		}
+		if r.r == nil {
+			return 0, io.EOF
+		}
+
+		n, err := r.r.Read(dest)
+		r.pos += int64(n)
+		r.seqBytes += int64(n)
+		r.lastEndPos = r.pos
+		atomic.AddInt64(&r.nread, int64(n))
+
+		if r.crc != nil && !r.crcBroken && n > 0 {
+			r.crc.Write(dest[:n])
+		}
+
+		if err == io.EOF && r.pos < r.size {
+			// The currently open range read ended, but the object
+			// hasn't: this was a bounded window (e.g. MinReadSize),
+			// not the whole object. Close it so the next iteration
+			// opens a fresh window starting at r.pos, rather than
+			// propagating EOF for an object that isn't actually
+			// exhausted.
+			if cerr := r.Close(); cerr != nil {
+				return n, cerr
+			}
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+
+		if cerr := r.finalizeCRC(); cerr != nil {
+			err = cerr
+		}
+
+		return n, err
 	}
-	n, err := r.r.Read(dest)
-	r.pos += int64(n)
-	atomic.AddInt64(&r.nread, int64(n))
-	return n, err
 }
 
-// Seek implements io.Seeker.
-func (r *Reader) Seek(offset int64, whence int) (int64, error) {
-	err := r.Close()
+// openRange opens a new underlying range read at r.pos with the given
+// length (-1 meaning to the end of the object), recording where it ends so
+// Seek and WriteTo can tell a bounded window from an unbounded one.
+func (r *Reader) openRange(length int64) error {
+	rc, err := r.obj.NewRangeReader(r.ctx, r.pos, length)
 	if err != nil {
-		return 0, err
+		return err
+	}
+	r.r = rc
+	if length < 0 {
+		r.rangeEnd = r.size
+	} else {
+		r.rangeEnd = r.pos + length
+	}
+	return nil
+}
+
+// finalizeCRC runs the stored-vs-computed CRC32C comparison the first time
+// Read reaches the end of the object with contiguity from byte 0 intact. It
+// is a no-op (and returns nil) if that comparison has already run, can
+// never run (no CRC32C available, or contiguity already broken by a seek),
+// or isn't due yet (r.pos hasn't reached r.size).
+func (r *Reader) finalizeCRC() error {
+	if r.crc == nil || r.crcBroken || r.crcDone || r.pos != r.size {
+		return nil
 	}
+	r.crcDone = true
+	if r.crc.Sum32() != r.wantCRC32C {
+		return ErrChecksumMismatch
+	}
+	r.verified = true
+	return nil
+}
 
+// Verified reports whether ReaderOptions.VerifyCRC32C was set, the object's
+// stored CRC32C was available, Read has reached the end of the object, the
+// stream was contiguous from offset 0 throughout (no intervening Seek), and
+// the computed CRC32C matched. Callers that need to know whether validation
+// actually happened — as opposed to having been silently skipped because of
+// a seek — should check this once Read returns io.EOF.
+func (r *Reader) Verified() bool {
+	return r.verified
+}
+
+// Seek implements io.Seeker.
+//
+// A forward seek of up to MaxForwardSkip bytes, while a range read is open
+// and that range read still has at least that many bytes left in it, is
+// satisfied by discarding the skipped bytes from that range read rather
+// than closing it, avoiding a new GCS range request. Backward seeks, and
+// forward seeks beyond that window or beyond what remains of it, close the
+// underlying range reader as before.
+//
+// Any seek that actually changes the read position — by either path —
+// breaks the contiguous-from-byte-0 invariant that ReaderOptions.VerifyCRC32C
+// relies on, since the skipped bytes never pass through Read.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
 	switch whence {
 	case io.SeekStart:
-		r.pos = offset
+		newPos = offset
 	case io.SeekCurrent:
-		r.pos += offset
+		newPos = r.pos + offset
 	case io.SeekEnd:
-		r.pos = r.size + offset
+		newPos = r.size + offset
 	default:
 		return 0, fmt.Errorf("illegal whence value %d", whence)
 	}
 
+	if newPos != r.pos {
+		r.crcBroken = true
+	}
+
+	if skip := newPos - r.pos; r.r != nil && skip >= 0 && skip <= r.opts.MaxForwardSkip && skip <= r.rangeEnd-r.pos {
+		if _, err := io.CopyN(io.Discard, r.r, skip); err != nil {
+			return 0, err
+		}
+		r.pos = newPos
+		r.seqBytes += skip
+		r.lastEndPos = newPos
+		return r.pos, nil
+	}
+
+	if err := r.Close(); err != nil {
+		return 0, err
+	}
+	r.pos = newPos
+
 	return r.pos, nil
 }
 
+// WriteTo implements io.WriterTo. It streams directly from the underlying
+// storage.Reader to dst instead of going through Read for every buffer, as
+// a generic io.Copy(dst, r) would, and delegates to dst's ReadFrom if dst
+// implements io.ReaderFrom; otherwise it copies with io.Copy and that
+// function's own buffer. Because the point of WriteTo is reading the
+// rest of the object in one go, it always reads from an unbounded range
+// read (to the end of the object) rather than the bounded window the
+// adaptive read heuristic would otherwise choose: a bounded window left
+// open by a prior Read is closed and reopened unbounded; an already-open
+// unbounded one is used as is.
+func (r *Reader) WriteTo(dst io.Writer) (int64, error) {
+	if !r.opts.Disabled {
+		if r.started && r.pos != r.lastEndPos {
+			r.seeks++
+			r.seqBytes = 0
+		}
+		r.started = true
+	}
+
+	if r.r != nil && r.rangeEnd < r.size {
+		if err := r.Close(); err != nil {
+			return 0, err
+		}
+	}
+	if r.r == nil && r.pos < r.size {
+		if err := r.openRange(-1); err != nil {
+			return 0, err
+		}
+	}
+	if r.r == nil {
+		return 0, nil
+	}
+
+	var src io.Reader = r.r
+	if r.crc != nil && !r.crcBroken {
+		src = io.TeeReader(r.r, r.crc)
+	}
+
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := dst.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(dst, src)
+	}
+
+	r.pos += n
+	r.seqBytes += n
+	r.lastEndPos = r.pos
+	atomic.AddInt64(&r.nread, n)
+
+	if cerr := r.finalizeCRC(); err == nil && cerr != nil {
+		err = cerr
+	}
+
+	return n, err
+}
+
 // Close closes a Reader and releases its resources.
 func (r *Reader) Close() error {
 	if r.r == nil {
@@ -92,6 +452,7 @@ func (r *Reader) Close() error {
 	}
 	err := r.r.Close()
 	r.r = nil
+	r.rangeEnd = 0
 	return err
 }
 
@@ -100,3 +461,105 @@ func (r *Reader) Close() error {
 func (r *Reader) NRead() int64 {
 	return atomic.LoadInt64(&r.nread)
 }
+
+// ReadAt implements io.ReaderAt. Unlike Read, it does not use or update the
+// Reader's seek position, so it is safe to call concurrently with Read,
+// Seek, and itself from multiple goroutines (e.g. from archive/zip's
+// zip.NewReader, or other range-indexed formats that expect an
+// io.ReaderAt).
+//
+// Each call opens one or more fresh range reads against the object; it does
+// not share the range reader used by Read. If ReaderOptions.ReadAtChunkSize
+// and ReaderOptions.ReadAtConcurrency are both set, a large ReadAt is split
+// into chunks fetched concurrently, up to ReadAtConcurrency at a time.
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	full := len(p)
+	want := int64(full)
+	if off+want > r.size {
+		want = r.size - off
+	}
+	p = p[:want]
+
+	var (
+		n   int
+		err error
+	)
+	if r.opts.ReadAtChunkSize > 0 && r.opts.ReadAtConcurrency > 1 && want > r.opts.ReadAtChunkSize {
+		n, err = r.readAtParallel(p, off)
+	} else {
+		n, err = r.readAtOnce(p, off)
+	}
+	atomic.AddInt64(&r.nread, int64(n))
+
+	if err != nil {
+		return n, err
+	}
+	if n < full {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readAtOnce fills p (exactly) with bytes from a single fresh range read
+// starting at off.
+func (r *Reader) readAtOnce(p []byte, off int64) (int, error) {
+	rc, err := r.obj.NewRangeReader(r.ctx, off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	return io.ReadFull(rc, p)
+}
+
+// readAtParallel fills p with bytes starting at off, split into
+// ReaderOptions.ReadAtChunkSize chunks each read by readAtOnce, with up to
+// ReaderOptions.ReadAtConcurrency chunks in flight at once.
+func (r *Reader) readAtParallel(p []byte, off int64) (int, error) {
+	chunkSize := r.opts.ReadAtChunkSize
+
+	sem := make(chan struct{}, r.opts.ReadAtConcurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for start := int64(0); start < int64(len(p)); start += chunkSize {
+		end := start + chunkSize
+		if end > int64(len(p)) {
+			end = int64(len(p))
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(chunk []byte, chunkOff int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := r.readAtOnce(chunk, chunkOff); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(p[start:end], off+start)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		// Other chunks may have already written their bytes into p even
+		// though we report n=0 here: unlike readAtOnce's single read, we
+		// have no single contiguous count of "bytes successfully read"
+		// to report when chunks fail independently, so callers must not
+		// assume p is untouched just because n==0.
+		return 0, firstErr
+	}
+	return len(p), nil
+}
@@ -0,0 +1,412 @@
+package gcsobj
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+// fakeOpener is a rangeOpener backed by an in-memory byte slice. It mimics
+// the one property of *storage.ObjectHandle.NewRangeReader that matters
+// here: a range read with a non-negative length yields an io.ReadCloser
+// that returns io.EOF once exactly that many bytes (or what's left of the
+// object, if fewer) have been read, regardless of how much of the object
+// remains.
+type fakeOpener struct {
+	data []byte
+}
+
+func (f *fakeOpener) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	end := int64(len(f.data))
+	if length >= 0 && offset+length < end {
+		end = offset + length
+	}
+	if offset > end {
+		offset = end
+	}
+	return io.NopCloser(bytes.NewReader(f.data[offset:end])), nil
+}
+
+// rangeCall records one call to a countingOpener's NewRangeReader.
+type rangeCall struct {
+	offset, length int64
+}
+
+// countingOpener wraps a fakeOpener and records every range read opened
+// against it, so tests can assert how many underlying requests a sequence
+// of Read/Seek calls produced and whether they were bounded or unbounded.
+type countingOpener struct {
+	fakeOpener
+	calls []rangeCall
+}
+
+func (c *countingOpener) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	c.calls = append(c.calls, rangeCall{offset, length})
+	return c.fakeOpener.NewRangeReader(ctx, offset, length)
+}
+
+// testData returns a deterministic, non-repeating byte slice of size n, so
+// that truncation or misordering in a test is easy to spot.
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	return data
+}
+
+// noUpgradeOptions returns ReaderOptions with a small MinReadSize and an
+// UpgradeBytesThreshold high enough that the adaptive heuristic never
+// upgrades to an unbounded range read, so every range opened during a test
+// stays a bounded, minReadSize-ish window.
+func noUpgradeOptions(minReadSize int64) ReaderOptions {
+	return ReaderOptions{
+		MinReadSize:           minReadSize,
+		UpgradeSeekThreshold:  2,
+		UpgradeBytesThreshold: 1 << 40,
+		MaxForwardSkip:        1 << 20,
+	}
+}
+
+func TestReadAcrossWindowBoundaries(t *testing.T) {
+	data := testData(10_000)
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  &fakeOpener{data: data},
+		size: int64(len(data)),
+		opts: noUpgradeOptions(100),
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("ReadAll returned %d bytes, want %d; a bounded window's EOF must not be treated as the object's EOF", len(got), len(data))
+	}
+}
+
+func TestSeekForwardBeyondOpenWindow(t *testing.T) {
+	data := testData(1_000)
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  &fakeOpener{data: data},
+		size: int64(len(data)),
+		opts: noUpgradeOptions(10), // small bounded windows
+	}
+
+	// Open a 10-byte window and consume 5 bytes of it, leaving 5 buffered.
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("initial Read: %v", err)
+	}
+	if !bytes.Equal(buf, data[:5]) {
+		t.Fatalf("initial Read returned %v, want %v", buf, data[:5])
+	}
+
+	// Seek forward by more than the 5 bytes left in the open window, but
+	// well within MaxForwardSkip. This must not fail even though the
+	// reuse path can't satisfy it from the buffered window alone.
+	const skip = 50
+	pos, err := r.Seek(5+skip, io.SeekStart)
+	if err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if pos != 5+skip {
+		t.Fatalf("Seek returned %d, want %d", pos, 5+skip)
+	}
+
+	got := make([]byte, 5)
+	if _, err := io.ReadFull(r, got); err != nil {
+		t.Fatalf("Read after Seek: %v", err)
+	}
+	want := data[5+skip : 5+skip+5]
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Read after Seek returned %v, want %v", got, want)
+	}
+}
+
+// erroringOpener wraps a rangeOpener and fails the one range read whose
+// offset matches failOffset, to exercise ReadAt's partial-failure path.
+type erroringOpener struct {
+	inner      rangeOpener
+	failOffset int64
+}
+
+func (o erroringOpener) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	if offset == o.failOffset {
+		return nil, errors.New("fake chunk failure")
+	}
+	return o.inner.NewRangeReader(ctx, offset, length)
+}
+
+func readAtOptions() ReaderOptions {
+	opts := noUpgradeOptions(1 << 20)
+	opts.ReadAtChunkSize = 64
+	opts.ReadAtConcurrency = 4
+	return opts
+}
+
+func TestReadAtParallelAssemblesChunks(t *testing.T) {
+	data := testData(1_000)
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  &fakeOpener{data: data},
+		size: int64(len(data)),
+		opts: readAtOptions(),
+	}
+
+	buf := make([]byte, 300)
+	n, err := r.ReadAt(buf, 111)
+	if err != nil {
+		t.Fatalf("ReadAt: %v", err)
+	}
+	if n != len(buf) {
+		t.Fatalf("ReadAt returned n=%d, want %d", n, len(buf))
+	}
+	want := data[111 : 111+300]
+	if !bytes.Equal(buf, want) {
+		t.Fatalf("ReadAt assembled %v, want %v", buf, want)
+	}
+}
+
+func TestReadAtParallelErrorReportsZeroN(t *testing.T) {
+	data := testData(1_000)
+	// 111+128 is the start offset of the third 64-byte chunk of a ReadAt(buf, 111).
+	opener := erroringOpener{inner: &fakeOpener{data: data}, failOffset: 111 + 128}
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  opener,
+		size: int64(len(data)),
+		opts: readAtOptions(),
+	}
+
+	buf := make([]byte, 300)
+	n, err := r.ReadAt(buf, 111)
+	if err == nil {
+		t.Fatalf("ReadAt: expected an error from the failing chunk, got nil")
+	}
+	// n is reported as 0 even though other chunks may already have
+	// written their bytes into buf; see the comment on readAtParallel.
+	if n != 0 {
+		t.Fatalf("ReadAt returned n=%d, want 0 on chunk failure", n)
+	}
+}
+
+func newCRCReader(data []byte, want uint32) *Reader {
+	return &Reader{
+		ctx:        context.Background(),
+		obj:        &fakeOpener{data: data},
+		size:       int64(len(data)),
+		opts:       noUpgradeOptions(1 << 20), // single window: MinReadSize > len(data)
+		wantCRC32C: want,
+		haveCRC32C: true,
+		crc:        crc32.New(crc32.MakeTable(crc32.Castagnoli)),
+	}
+}
+
+func TestCRC32CVerificationMatches(t *testing.T) {
+	data := testData(1_000)
+	want := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+	r := newCRCReader(data, want)
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("ReadAll returned %v, want %v", got, data)
+	}
+	if !r.Verified() {
+		t.Fatalf("Verified() = false, want true after a full, contiguous, matching read")
+	}
+}
+
+func TestCRC32CVerificationMismatch(t *testing.T) {
+	data := testData(1_000)
+	r := newCRCReader(data, 0 /* wrong */)
+
+	_, err := io.ReadAll(r)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("ReadAll error = %v, want ErrChecksumMismatch", err)
+	}
+	if r.Verified() {
+		t.Fatalf("Verified() = true, want false after a mismatch")
+	}
+}
+
+func TestCRC32CVerificationSkippedAfterSeek(t *testing.T) {
+	data := testData(1_000)
+	want := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+	r := newCRCReader(data, want)
+
+	if _, err := r.Seek(10, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, data[10:]) {
+		t.Fatalf("ReadAll returned %v, want %v", got, data[10:])
+	}
+	if r.Verified() {
+		t.Fatalf("Verified() = true, want false: a seek broke contiguity from byte 0, so verification must be skipped, not silently passed")
+	}
+}
+
+func TestWriteToAfterBoundedReadReadsWholeObject(t *testing.T) {
+	data := testData(1_000)
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  &fakeOpener{data: data},
+		size: int64(len(data)),
+		opts: noUpgradeOptions(10), // small bounded windows
+	}
+
+	// Open a 10-byte window and consume only 5 bytes of it, leaving an
+	// open, bounded (non-upgraded) r.r with 5 bytes still buffered.
+	small := make([]byte, 5)
+	if _, err := io.ReadFull(r, small); err != nil {
+		t.Fatalf("initial Read: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	want := data[5:]
+	if n != int64(len(want)) {
+		t.Fatalf("WriteTo returned n=%d, want %d", n, len(want))
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("WriteTo copied %d bytes, want the rest of the object (%d bytes); got truncated at the bounded window's own EOF", buf.Len(), len(want))
+	}
+}
+
+// upgradeTestOptions returns ReaderOptions small enough to exercise the
+// upgrade/downgrade heuristic within a test-sized object.
+func upgradeTestOptions() ReaderOptions {
+	return ReaderOptions{
+		MinReadSize:           1000,
+		UpgradeSeekThreshold:  2,
+		UpgradeBytesThreshold: 5000,
+		MaxForwardSkip:        1 << 20,
+	}
+}
+
+func TestRangeLengthUpgradesAfterSustainedSequentialReads(t *testing.T) {
+	data := testData(50_000)
+	opener := &countingOpener{fakeOpener: fakeOpener{data: data}}
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  opener,
+		size: int64(len(data)),
+		opts: upgradeTestOptions(),
+	}
+
+	// A few scattered jumps, as in a zip/tar indexer reading a header and
+	// footer before streaming file contents, push r.seeks past
+	// UpgradeSeekThreshold.
+	positions := []int64{1000, 20000, 100, 15000}
+	for _, pos := range positions {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			t.Fatalf("Seek(%d): %v", pos, err)
+		}
+		small := make([]byte, 10)
+		if _, err := io.ReadFull(r, small); err != nil {
+			t.Fatalf("Read after Seek(%d): %v", pos, err)
+		}
+	}
+	if r.seeks <= int64(r.opts.UpgradeSeekThreshold) {
+		t.Fatalf("test setup: want r.seeks > UpgradeSeekThreshold, got %d", r.seeks)
+	}
+
+	// Now read the rest of the object purely sequentially. Despite the
+	// earlier seeks, enough sustained sequential access should still
+	// upgrade the Reader to a single unbounded range read for the
+	// remainder, rather than staying stuck issuing MinReadSize windows
+	// forever.
+	lastPos := positions[len(positions)-1] + 10
+	opener.calls = nil
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(rest, data[lastPos:]) {
+		t.Fatalf("ReadAll returned %d bytes, want the remaining %d", len(rest), len(data)-int(lastPos))
+	}
+
+	var unbounded int
+	for _, c := range opener.calls {
+		if c.length < 0 {
+			unbounded++
+		}
+	}
+	if unbounded == 0 {
+		t.Fatalf("expected at least one unbounded range read once sequential access resumed, got calls=%v", opener.calls)
+	}
+}
+
+func TestRangeLengthDowngradesAfterManySeeks(t *testing.T) {
+	data := testData(50_000)
+	opener := &countingOpener{fakeOpener: fakeOpener{data: data}}
+	r := &Reader{
+		ctx:  context.Background(),
+		obj:  opener,
+		size: int64(len(data)),
+		opts: upgradeTestOptions(),
+	}
+
+	// Read sequentially past UpgradeBytesThreshold to upgrade to an
+	// unbounded range read.
+	buf := make([]byte, 6000)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("initial sequential Read: %v", err)
+	}
+	var sawUnbounded bool
+	for _, c := range opener.calls {
+		if c.length < 0 {
+			sawUnbounded = true
+		}
+	}
+	if !sawUnbounded {
+		t.Fatalf("test setup: want an unbounded range read after a long sequential run, got calls=%v", opener.calls)
+	}
+
+	// Enough scattered seeks to push r.seeks back past
+	// UpgradeSeekThreshold, with too few sequential bytes between them to
+	// re-earn the upgrade.
+	for _, pos := range []int64{100, 40000, 200, 30000} {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			t.Fatalf("Seek(%d): %v", pos, err)
+		}
+		small := make([]byte, 5)
+		if _, err := io.ReadFull(r, small); err != nil {
+			t.Fatalf("Read after Seek(%d): %v", pos, err)
+		}
+	}
+	if r.seeks <= int64(r.opts.UpgradeSeekThreshold) {
+		t.Fatalf("test setup: want r.seeks > UpgradeSeekThreshold, got %d", r.seeks)
+	}
+
+	// A final forward seek beyond the currently open window forces a
+	// fresh range read, which should now be a bounded MinReadSize window
+	// again, not unbounded.
+	if _, err := r.Seek(45000, io.SeekStart); err != nil {
+		t.Fatalf("Seek(45000): %v", err)
+	}
+	opener.calls = nil
+	small := make([]byte, 5)
+	if _, err := io.ReadFull(r, small); err != nil {
+		t.Fatalf("final Read: %v", err)
+	}
+	if len(opener.calls) != 1 || opener.calls[0].length < 0 {
+		t.Fatalf("expected a single bounded range read after many seeks, got calls=%v", opener.calls)
+	}
+}